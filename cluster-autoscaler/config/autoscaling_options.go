@@ -0,0 +1,34 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+// NodeGroupDifferenceRatios contains various ratios used to determine if two node groups are
+// similar enough to be balanced together, plus which algorithm balances a scale-up across them.
+type NodeGroupDifferenceRatios struct {
+	// MaxCapacityMemoryDifferenceRatio is the maximum relative difference in Capacity.Memory
+	// between two nodes for their node groups to be considered similar.
+	MaxCapacityMemoryDifferenceRatio float64
+	// MaxAllocatableDifferenceRatio is the maximum relative difference in Allocatable between two
+	// nodes for their node groups to be considered similar.
+	MaxAllocatableDifferenceRatio float64
+	// BalancingStrategy selects, by name, the BalanceFunc (see
+	// nodegroupset.RegisterBalancingStrategy) that spreads a scale-up across similar node groups,
+	// e.g. "even", "weighted", "priority" or "least-waste", or any strategy an out-of-tree cloud
+	// provider has registered. An empty value, or one that isn't registered, falls back to
+	// "even". Populated from the --balancing-strategy flag.
+	BalancingStrategy string
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"math"
+	"sort"
+
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	klog "k8s.io/klog/v2"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+)
+
+// BalancingNodeGroupSetProcessor balances scale-up between similar node groups, giving each of
+// them an equal share of the new nodes (modulo Max size caps).
+type BalancingNodeGroupSetProcessor struct {
+	Comparator NodeInfoComparator
+}
+
+// FindSimilarNodeGroups returns a list of NodeGroups similar to the one provided in parameter.
+func (b *BalancingNodeGroupSetProcessor) FindSimilarNodeGroups(context *context.AutoscalingContext, nodeGroup cloudprovider.NodeGroup,
+	nodeInfosForGroups map[string]*schedulerframework.NodeInfo) ([]cloudprovider.NodeGroup, errors.AutoscalerError) {
+
+	result := make([]cloudprovider.NodeGroup, 0)
+	nodeGroupId := nodeGroup.Id()
+	nodeInfo, found := nodeInfosForGroups[nodeGroupId]
+	if !found {
+		return nil, errors.NewAutoscalerError(errors.InternalError, "failed to find nodeInfo for node group %v", nodeGroupId)
+	}
+
+	for _, ng := range context.CloudProvider.NodeGroups() {
+		if ng.Id() == nodeGroupId {
+			continue
+		}
+		ngNodeInfo, found := nodeInfosForGroups[ng.Id()]
+		if !found {
+			klog.Warningf("Failed to find nodeInfo for group %v", ng.Id())
+			continue
+		}
+		if b.Comparator(nodeInfo, ngNodeInfo) {
+			result = append(result, ng)
+		}
+	}
+	return result, nil
+}
+
+// BalanceScaleUpBetweenGroups distributes newNodes evenly (up to rounding caused by Max size caps)
+// between the given set of similar node groups.
+func (b *BalancingNodeGroupSetProcessor) BalanceScaleUpBetweenGroups(context *context.AutoscalingContext, groups []cloudprovider.NodeGroup, newNodes int) ([]ScaleUpInfo, errors.AutoscalerError) {
+	scaleUpInfos, err := buildScaleUpInfos(groups)
+	if err != nil {
+		return nil, err
+	}
+	distributeScaleUpBetweenGroups(scaleUpInfos, newNodes, evenWeight)
+	return changedScaleUpInfos(scaleUpInfos), nil
+}
+
+// CleanUp performs final clean up of processor state.
+func (b *BalancingNodeGroupSetProcessor) CleanUp() {
+}
+
+func evenWeight(_ string) int {
+	return 1
+}
+
+// buildScaleUpInfos builds the initial, unscaled-up ScaleUpInfo for every group that still has
+// room to grow, skipping groups that are already at (or past) their Max size.
+func buildScaleUpInfos(groups []cloudprovider.NodeGroup) ([]*ScaleUpInfo, errors.AutoscalerError) {
+	scaleUpInfos := make([]*ScaleUpInfo, 0, len(groups))
+	for _, ng := range groups {
+		currentSize, err := ng.TargetSize()
+		if err != nil {
+			return nil, errors.ToAutoscalerError(errors.CloudProviderError, err)
+		}
+		maxSize := ng.MaxSize()
+		if currentSize >= maxSize {
+			continue
+		}
+		scaleUpInfos = append(scaleUpInfos, &ScaleUpInfo{
+			Group:       ng,
+			CurrentSize: currentSize,
+			NewSize:     currentSize,
+			MaxSize:     maxSize,
+		})
+	}
+	return scaleUpInfos, nil
+}
+
+func changedScaleUpInfos(infos []*ScaleUpInfo) []ScaleUpInfo {
+	result := make([]ScaleUpInfo, 0, len(infos))
+	for _, info := range infos {
+		if info.NewSize > info.CurrentSize {
+			result = append(result, *info)
+		}
+	}
+	return result
+}
+
+// distributeScaleUpBetweenGroups performs a weighted water-filling distribution of newNodes across
+// infos. Each group's weight (from weightFunc) determines how much of newNodes it is entitled to
+// relative to the others: a group with twice the weight of another receives twice as many new
+// nodes, modulo Max size caps. At every step the group(s) with the lowest currentSize/weight ratio
+// are grown together until they either catch up to the next ratio level or hit their Max, at which
+// point they're dropped from the pool and the remaining nodes are redistributed. Passing a
+// weightFunc that always returns 1 degenerates to a strictly even split. A weight <= 0 is treated
+// as 1, so a caller-supplied weightFunc that doesn't cover every group can't divide by zero or
+// silently strand a group at +Inf/NaN in the ratio ordering.
+func distributeScaleUpBetweenGroups(infos []*ScaleUpInfo, newNodes int, weightFunc func(id string) int) {
+	weightFunc = safeWeight(weightFunc)
+	active := make([]*ScaleUpInfo, 0, len(infos))
+	for _, info := range infos {
+		if info.NewSize < info.MaxSize {
+			active = append(active, info)
+		}
+	}
+
+	remaining := newNodes
+	for remaining > 0 && len(active) > 0 {
+		sort.Slice(active, func(i, j int) bool {
+			ri, rj := ratio(active[i], weightFunc), ratio(active[j], weightFunc)
+			if ri != rj {
+				return ri < rj
+			}
+			// Break ties deterministically so repeated calls with the same input always
+			// distribute the last, non-evenly-divisible node the same way.
+			return active[i].Group.Id() < active[j].Group.Id()
+		})
+
+		level := ratio(active[0], weightFunc)
+		tiedCount := 1
+		for tiedCount < len(active) && ratio(active[tiedCount], weightFunc) == level {
+			tiedCount++
+		}
+		tied := active[:tiedCount]
+
+		totalWeight := 0
+		minCapUnits := math.Inf(1)
+		for _, info := range tied {
+			w := weightFunc(info.Group.Id())
+			totalWeight += w
+			if capUnits := float64(info.MaxSize-info.NewSize) / float64(w); capUnits < minCapUnits {
+				minCapUnits = capUnits
+			}
+		}
+
+		nextLevel := math.Inf(1)
+		if tiedCount < len(active) {
+			nextLevel = ratio(active[tiedCount], weightFunc)
+		}
+		step := math.Min(nextLevel-level, minCapUnits)
+
+		maxAdd := 0
+		for _, info := range tied {
+			maxAdd += int(step * float64(weightFunc(info.Group.Id())))
+		}
+
+		if step > 0 && maxAdd > 0 && maxAdd <= remaining {
+			for _, info := range tied {
+				info.NewSize += int(step * float64(weightFunc(info.Group.Id())))
+			}
+			remaining -= maxAdd
+		} else {
+			floorAssignRemainder(tied, remaining, totalWeight, weightFunc)
+			remaining = 0
+		}
+
+		filtered := active[:0]
+		for _, info := range active {
+			if info.NewSize < info.MaxSize {
+				filtered = append(filtered, info)
+			}
+		}
+		active = filtered
+	}
+}
+
+// floorAssignRemainder splits the last `remaining` nodes across the tied groups proportionally to
+// weight, floor-assigning whole nodes and handing the leftover (caused by rounding) to the
+// least-loaded groups, capped by each group's remaining room to its Max size.
+func floorAssignRemainder(tied []*ScaleUpInfo, remaining, totalWeight int, weightFunc func(id string) int) {
+	assigned := make([]int, len(tied))
+	used := 0
+	for i, info := range tied {
+		assigned[i] = remaining * weightFunc(info.Group.Id()) / totalWeight
+		used += assigned[i]
+	}
+	leftover := remaining - used
+	for i := 0; leftover > 0 && i < len(tied); i++ {
+		assigned[i]++
+		leftover--
+	}
+	for i, info := range tied {
+		if room := info.MaxSize - info.NewSize; assigned[i] > room {
+			assigned[i] = room
+		}
+		info.NewSize += assigned[i]
+	}
+}
+
+func ratio(info *ScaleUpInfo, weightFunc func(id string) int) float64 {
+	return float64(info.NewSize) / float64(weightFunc(info.Group.Id()))
+}
+
+// safeWeight wraps weightFunc so it never returns a non-positive weight, avoiding a division by
+// zero (or a negative divisor) in ratio().
+func safeWeight(weightFunc func(id string) int) func(id string) int {
+	return func(id string) int {
+		if w := weightFunc(id); w > 0 {
+			return w
+		}
+		return 1
+	}
+}
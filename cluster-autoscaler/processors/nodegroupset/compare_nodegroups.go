@@ -0,0 +1,112 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"math"
+
+	apiv1 "k8s.io/api/core/v1"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+)
+
+// basicIgnoredLabels are labels that are expected to differ between nodes that otherwise belong
+// to the same logical node group (e.g. the node's own hostname), and so are never compared.
+var basicIgnoredLabels = map[string]bool{
+	apiv1.LabelHostname: true,
+}
+
+// CreateGenericNodeInfoComparator returns a generic comparator that decides if two nodes belong to
+// the same NodeGroupSet by comparing their capacity and allocatable resources within the given
+// tolerance ratios, ignoring the given set of labels (in addition to a handful of labels that are
+// expected to differ between otherwise identical node groups).
+func CreateGenericNodeInfoComparator(extraIgnoredLabels []string, ratioOpts config.NodeGroupDifferenceRatios) NodeInfoComparator {
+	ignoredLabels := make(map[string]bool)
+	for k, v := range basicIgnoredLabels {
+		ignoredLabels[k] = v
+	}
+	for _, k := range extraIgnoredLabels {
+		ignoredLabels[k] = true
+	}
+
+	return func(n1, n2 *schedulerframework.NodeInfo) bool {
+		return IsCloudProviderNodeInfoSimilar(n1, n2, ignoredLabels, ratioOpts)
+	}
+}
+
+// IsCloudProviderNodeInfoSimilar returns true if two NodeInfos are similar enough to be considered
+// part of the same NodeGroupSet. Nodes are considered similar if their capacity and allocatable
+// resources are within the configured ratios, and if they have the same labels (modulo the ignored
+// ones).
+func IsCloudProviderNodeInfoSimilar(n1, n2 *schedulerframework.NodeInfo, ignoredLabels map[string]bool,
+	ratioOpts config.NodeGroupDifferenceRatios) bool {
+	if !compareResourceMapsWithTolerance(n1.Node().Status.Capacity, n2.Node().Status.Capacity, ratioOpts.MaxCapacityMemoryDifferenceRatio) {
+		return false
+	}
+	if !compareResourceMapsWithTolerance(n1.Node().Status.Allocatable, n2.Node().Status.Allocatable, ratioOpts.MaxAllocatableDifferenceRatio) {
+		return false
+	}
+	return compareLabels(n1.Node().ObjectMeta.Labels, n2.Node().ObjectMeta.Labels, ignoredLabels)
+}
+
+// compareResourceMapsWithTolerance returns true if, for every resource present in either map, the
+// relative difference between the two quantities is no larger than maxDifferenceRatio. A ratio of
+// 0 requires an exact match.
+func compareResourceMapsWithTolerance(resources1, resources2 apiv1.ResourceList, maxDifferenceRatio float64) bool {
+	names := make(map[apiv1.ResourceName]bool)
+	for name := range resources1 {
+		names[name] = true
+	}
+	for name := range resources2 {
+		names[name] = true
+	}
+	for name := range names {
+		q1 := resources1[name]
+		q2 := resources2[name]
+		v1 := float64(q1.MilliValue())
+		v2 := float64(q2.MilliValue())
+		larger := math.Max(v1, v2)
+		if larger == 0 {
+			continue
+		}
+		if math.Abs(v1-v2)/larger > maxDifferenceRatio {
+			return false
+		}
+	}
+	return true
+}
+
+func compareLabels(labels1, labels2 map[string]string, ignoredLabels map[string]bool) bool {
+	for k, v := range labels1 {
+		if ignoredLabels[k] {
+			continue
+		}
+		if labels2[k] != v {
+			return false
+		}
+	}
+	for k := range labels2 {
+		if ignoredLabels[k] {
+			continue
+		}
+		if _, found := labels1[k]; !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"fmt"
+
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	klog "k8s.io/klog/v2"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+)
+
+// ScaleUpInfo contains information about planned scale-up of a single NodeGroup
+type ScaleUpInfo struct {
+	// Group is the node group to be scaled-up
+	Group cloudprovider.NodeGroup
+	// CurrentSize is the current size of the Group
+	CurrentSize int
+	// NewSize is the size the Group will be scaled-up to
+	NewSize int
+	// MaxSize is the maximum allowed size of the Group
+	MaxSize int
+	// Reason explains why NewSize is above CurrentSize, e.g. demand-driven vs. rotation-driven.
+	// It's empty for processors that don't distinguish a reason.
+	Reason ScaleUpReason
+}
+
+// String is used for printing ScaleUpInfo for logging, etc.
+func (s ScaleUpInfo) String() string {
+	return fmt.Sprintf("{%v %v->%v (max: %v) reason: %v}", s.Group.Id(), s.CurrentSize, s.NewSize, s.MaxSize, s.Reason)
+}
+
+// NodeInfoComparator is a function that tells if two NodeInfos belong to the
+// same NodeGroupSet.
+type NodeInfoComparator func(n1, n2 *schedulerframework.NodeInfo) bool
+
+// NodeGroupSetProcessor finds nodegroups that are similar and allows balancing
+// scale-up between them.
+type NodeGroupSetProcessor interface {
+	FindSimilarNodeGroups(context *context.AutoscalingContext, nodeGroup cloudprovider.NodeGroup,
+		nodeInfosForGroups map[string]*schedulerframework.NodeInfo) ([]cloudprovider.NodeGroup, errors.AutoscalerError)
+
+	BalanceScaleUpBetweenGroups(context *context.AutoscalingContext, groups []cloudprovider.NodeGroup, newNodes int) ([]ScaleUpInfo, errors.AutoscalerError)
+
+	CleanUp()
+}
+
+// NewDefaultNodeGroupSetProcessor returns a default instance of NodeGroupSetProcessor. The
+// balancing algorithm used to spread a scale-up across similar node groups is selected by
+// ratioOpts.BalancingStrategy (see RegisterBalancingStrategy); an empty value, or one that isn't
+// registered, falls back to the built-in "even" strategy.
+//
+// This constructor has no per-group ranking data to offer a strategy (no weight, priority tier or
+// waste score), so every group is ranked equally: strategies that only care about relative rank
+// ("priority", "least-waste") degrade to filling groups in Id order, and "weighted" degrades to an
+// even split. A strategy that needs a real ranking should be built directly via
+// StrategyNodeGroupSetProcessor with Rank populated from the relevant per-group configuration.
+func NewDefaultNodeGroupSetProcessor(ignoredLabels []string, ratioOpts config.NodeGroupDifferenceRatios) NodeGroupSetProcessor {
+	strategy, err := GetBalancingStrategy(ratioOpts.BalancingStrategy)
+	if err != nil {
+		klog.Errorf("invalid BalancingStrategy %q, falling back to \"even\": %v", ratioOpts.BalancingStrategy, err)
+		strategy, _ = GetBalancingStrategy("even")
+	} else if ratioOpts.BalancingStrategy != "" && ratioOpts.BalancingStrategy != "even" {
+		klog.Warningf("BalancingStrategy %q ranks groups equally here; construct a StrategyNodeGroupSetProcessor directly with Rank set to make it rank-aware", ratioOpts.BalancingStrategy)
+	}
+	return &StrategyNodeGroupSetProcessor{
+		BalancingNodeGroupSetProcessor: BalancingNodeGroupSetProcessor{
+			Comparator: CreateGenericNodeInfoComparator(ignoredLabels, ratioOpts),
+		},
+		Strategy: strategy,
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+// BuildBasicNodeGroups builds three NodeInfos to be used as the basis of NodeGroupSetProcessor
+// tests: ni1 and ni2 describe identically-shaped nodes and are expected to be considered similar,
+// while ni3 describes a differently-shaped node and is expected to be considered dissimilar to
+// either of them.
+func BuildBasicNodeGroups(context *context.AutoscalingContext) (*schedulerframework.NodeInfo, *schedulerframework.NodeInfo, *schedulerframework.NodeInfo) {
+	node1 := BuildTestNode("n1", 1000, 1000)
+	ni1 := schedulerframework.NewNodeInfo()
+	ni1.SetNode(node1)
+
+	node2 := BuildTestNode("n2", 1000, 1000)
+	ni2 := schedulerframework.NewNodeInfo()
+	ni2.SetNode(node2)
+
+	node3 := BuildTestNode("n3", 2000, 2000)
+	ni3 := schedulerframework.NewNodeInfo()
+	ni3.SetNode(node3)
+
+	return ni1, ni2, ni3
+}
+
+// BasicSimilarNodeGroupsTest wires ni1, ni2 and ni3 up as three single-node node groups on a test
+// cloud provider and asserts that processor.FindSimilarNodeGroups considers ng1 and ng2 similar to
+// one another, but neither similar to ng3.
+func BasicSimilarNodeGroupsTest(t *testing.T, context *context.AutoscalingContext, processor NodeGroupSetProcessor,
+	ni1, ni2, ni3 *schedulerframework.NodeInfo) {
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNodeGroup("ng2", 1, 10, 1)
+	provider.AddNodeGroup("ng3", 1, 10, 1)
+	provider.AddNode("ng1", ni1.Node())
+	provider.AddNode("ng2", ni2.Node())
+	provider.AddNode("ng3", ni3.Node())
+	context.CloudProvider = provider
+
+	nodeInfosForGroups := map[string]*schedulerframework.NodeInfo{
+		"ng1": ni1,
+		"ng2": ni2,
+		"ng3": ni3,
+	}
+
+	ng1, err := provider.NodeGroupForNode(ni1.Node())
+	assert.NoError(t, err)
+	similar, aerr := processor.FindSimilarNodeGroups(context, ng1, nodeInfosForGroups)
+	assert.NoError(t, aerr)
+	assert.Equal(t, 1, len(similar))
+	assert.Equal(t, "ng2", similar[0].Id())
+
+	ng3, err := provider.NodeGroupForNode(ni3.Node())
+	assert.NoError(t, err)
+	similar, aerr = processor.FindSimilarNodeGroups(context, ng3, nodeInfosForGroups)
+	assert.NoError(t, aerr)
+	assert.Equal(t, 0, len(similar))
+}
+
+// toScaleUpMap indexes a BalanceScaleUpBetweenGroups result by group Id for easy lookup in tests.
+func toScaleUpMap(suiList []ScaleUpInfo) map[string]ScaleUpInfo {
+	result := make(map[string]ScaleUpInfo, len(suiList))
+	for _, sui := range suiList {
+		result[sui.Group.Id()] = sui
+	}
+	return result
+}
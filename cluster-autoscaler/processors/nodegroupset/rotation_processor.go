@@ -0,0 +1,150 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"time"
+
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+)
+
+// ScaleUpReason distinguishes why a ScaleUpInfo's NewSize is above its CurrentSize.
+type ScaleUpReason string
+
+const (
+	// ScaleUpReasonDemand means the extra node(s) are needed to satisfy the requested newNodes.
+	ScaleUpReasonDemand ScaleUpReason = "demand"
+	// ScaleUpReasonRotation means the extra node is a provisional +1 to enable rolling
+	// replacement of a node older than the group's configured MaxNodeAge; the downscaler is
+	// expected to remove the oldest node once the replacement is ready.
+	ScaleUpReasonRotation ScaleUpReason = "rotation"
+)
+
+// NodeRotationInfo describes the oldest node currently in a node group, as needed to decide
+// whether the group requires a forced rotation scale-up.
+type NodeRotationInfo struct {
+	// OldestNodeAge is the age of the oldest node currently in the group.
+	OldestNodeAge time.Duration
+	// OldestNodeTainted is true if the oldest node is already tainted (e.g. scheduled for
+	// deletion), in which case it shouldn't also trigger a rotation scale-up.
+	OldestNodeTainted bool
+}
+
+// NodeRotationInfoFunc returns rotation information for the node group with the given Id().
+type NodeRotationInfoFunc func(groupId string) (NodeRotationInfo, error)
+
+// RotationAwareNodeGroupSetProcessor wraps another NodeGroupSetProcessor and adds a max-node-age
+// forced rotation trigger: a group that has reached its minimum size but whose oldest node is
+// older than its configured MaxNodeAge is given a provisional +1 scale-up, so the downscaler can
+// later remove the aged-out node once its replacement is ready. Groups that also need a
+// demand-driven scale-up (as decided by Base) are left to Base and never get a rotation delta on
+// top, since they're already growing.
+type RotationAwareNodeGroupSetProcessor struct {
+	Base NodeGroupSetProcessor
+	// MaxNodeAge maps a node group Id() to the age after which its oldest node should be
+	// force-rotated. Groups missing from the map, or configured with MaxNodeAge <= 0, never
+	// trigger a forced rotation.
+	MaxNodeAge map[string]time.Duration
+	// RotationInfo returns the oldest node's age and taint status for a node group. A nil
+	// RotationInfo disables the rotation trigger entirely, same as an empty MaxNodeAge.
+	RotationInfo NodeRotationInfoFunc
+}
+
+// NewRotationAwareNodeGroupSetProcessor returns a NodeGroupSetProcessor that delegates similarity
+// and demand-driven balancing to base, and additionally forces a +1 rotation scale-up on groups at
+// MinSize whose oldest node has exceeded its configured MaxNodeAge.
+func NewRotationAwareNodeGroupSetProcessor(base NodeGroupSetProcessor, maxNodeAge map[string]time.Duration, rotationInfo NodeRotationInfoFunc) NodeGroupSetProcessor {
+	return &RotationAwareNodeGroupSetProcessor{
+		Base:         base,
+		MaxNodeAge:   maxNodeAge,
+		RotationInfo: rotationInfo,
+	}
+}
+
+// FindSimilarNodeGroups delegates to Base.
+func (r *RotationAwareNodeGroupSetProcessor) FindSimilarNodeGroups(context *context.AutoscalingContext, nodeGroup cloudprovider.NodeGroup,
+	nodeInfosForGroups map[string]*schedulerframework.NodeInfo) ([]cloudprovider.NodeGroup, errors.AutoscalerError) {
+	return r.Base.FindSimilarNodeGroups(context, nodeGroup, nodeInfosForGroups)
+}
+
+// CleanUp delegates to Base.
+func (r *RotationAwareNodeGroupSetProcessor) CleanUp() {
+	r.Base.CleanUp()
+}
+
+// BalanceScaleUpBetweenGroups first delegates to Base to compute a demand-driven distribution of
+// newNodes, tagging the result as ScaleUpReasonDemand, then appends a ScaleUpReasonRotation entry
+// for every group that is at MinSize, below MaxSize, configured with MaxNodeAge > 0, and whose
+// oldest node is untainted and older than MaxNodeAge.
+func (r *RotationAwareNodeGroupSetProcessor) BalanceScaleUpBetweenGroups(context *context.AutoscalingContext, groups []cloudprovider.NodeGroup, newNodes int) ([]ScaleUpInfo, errors.AutoscalerError) {
+	demand, err := r.Base.BalanceScaleUpBetweenGroups(context, groups, newNodes)
+	if err != nil {
+		return nil, err
+	}
+
+	scaledUp := make(map[string]bool, len(demand))
+	result := make([]ScaleUpInfo, 0, len(demand))
+	for _, info := range demand {
+		info.Reason = ScaleUpReasonDemand
+		scaledUp[info.Group.Id()] = true
+		result = append(result, info)
+	}
+
+	for _, ng := range groups {
+		id := ng.Id()
+		if scaledUp[id] {
+			continue
+		}
+		maxNodeAge, configured := r.MaxNodeAge[id]
+		if !configured || maxNodeAge <= 0 {
+			continue
+		}
+		currentSize, err := ng.TargetSize()
+		if err != nil {
+			return nil, errors.ToAutoscalerError(errors.CloudProviderError, err)
+		}
+		if currentSize != ng.MinSize() {
+			continue
+		}
+		maxSize := ng.MaxSize()
+		if currentSize >= maxSize {
+			continue
+		}
+		if r.RotationInfo == nil {
+			continue
+		}
+		rotationInfo, rerr := r.RotationInfo(id)
+		if rerr != nil {
+			return nil, errors.ToAutoscalerError(errors.CloudProviderError, rerr)
+		}
+		if rotationInfo.OldestNodeTainted || rotationInfo.OldestNodeAge < maxNodeAge {
+			continue
+		}
+		result = append(result, ScaleUpInfo{
+			Group:       ng,
+			CurrentSize: currentSize,
+			NewSize:     currentSize + 1,
+			MaxSize:     maxSize,
+			Reason:      ScaleUpReasonRotation,
+		})
+	}
+	return result, nil
+}
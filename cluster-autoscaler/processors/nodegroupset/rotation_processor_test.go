@@ -0,0 +1,125 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+)
+
+func newRotationProcessor(t *testing.T, maxNodeAge map[string]time.Duration, rotationInfo NodeRotationInfoFunc) NodeGroupSetProcessor {
+	base := NewDefaultNodeGroupSetProcessor([]string{}, config.NodeGroupDifferenceRatios{})
+	return NewRotationAwareNodeGroupSetProcessor(base, maxNodeAge, rotationInfo)
+}
+
+func TestRotationSkippedWhenOldestNodeTainted(t *testing.T) {
+	processor := newRotationProcessor(t, map[string]time.Duration{"ng1": time.Hour},
+		func(groupId string) (NodeRotationInfo, error) {
+			return NodeRotationInfo{OldestNodeAge: 2 * time.Hour, OldestNodeTainted: true}, nil
+		})
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(scaleUpInfo))
+}
+
+func TestRotationForcedWhenOldestNodeHealthyAndOld(t *testing.T) {
+	processor := newRotationProcessor(t, map[string]time.Duration{"ng1": time.Hour},
+		func(groupId string) (NodeRotationInfo, error) {
+			return NodeRotationInfo{OldestNodeAge: 2 * time.Hour, OldestNodeTainted: false}, nil
+		})
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(scaleUpInfo))
+	assert.Equal(t, "ng1", scaleUpInfo[0].Group.Id())
+	assert.Equal(t, 2, scaleUpInfo[0].NewSize)
+	assert.Equal(t, ScaleUpReasonRotation, scaleUpInfo[0].Reason)
+}
+
+func TestRotationNoopWhenNodesYoungerThanThreshold(t *testing.T) {
+	processor := newRotationProcessor(t, map[string]time.Duration{"ng1": time.Hour},
+		func(groupId string) (NodeRotationInfo, error) {
+			return NodeRotationInfo{OldestNodeAge: 10 * time.Minute, OldestNodeTainted: false}, nil
+		})
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(scaleUpInfo))
+}
+
+func TestRotationDisabledWhenMaxNodeAgeNotPositive(t *testing.T) {
+	processor := newRotationProcessor(t, map[string]time.Duration{"ng1": 0, "ng2": -time.Hour},
+		func(groupId string) (NodeRotationInfo, error) {
+			t.Fatalf("RotationInfo should not be called for a group with MaxNodeAge <= 0")
+			return NodeRotationInfo{}, nil
+		})
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNodeGroup("ng2", 1, 10, 1)
+
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(scaleUpInfo))
+}
+
+func TestRotationDisabledWhenRotationInfoIsNil(t *testing.T) {
+	processor := newRotationProcessor(t, map[string]time.Duration{"ng1": time.Hour}, nil)
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(scaleUpInfo))
+}
+
+func TestRotationDisabledWhenMaxNodeAgeNotConfigured(t *testing.T) {
+	processor := newRotationProcessor(t, map[string]time.Duration{}, func(groupId string) (NodeRotationInfo, error) {
+		t.Fatalf("RotationInfo should not be called for a group with no MaxNodeAge configured")
+		return NodeRotationInfo{}, nil
+	})
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(scaleUpInfo))
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+)
+
+// StrategyNodeGroupSetProcessor balances scale-up between similar node groups using a named,
+// pluggable BalanceFunc (see RegisterBalancingStrategy) rather than being hard-wired to a single
+// allocation algorithm.
+type StrategyNodeGroupSetProcessor struct {
+	BalancingNodeGroupSetProcessor
+	// Strategy is the BalanceFunc this processor delegates to.
+	Strategy BalanceFunc
+	// Rank supplies the strategy-specific per-group ranking input (see BalanceFunc). A nil Rank
+	// defaults every group to a rank of 1, which is what the built-in "even" strategy expects.
+	Rank func(id string) int
+}
+
+// BalanceScaleUpBetweenGroups filters groups down to the ones that still have room to grow, then
+// delegates distributing newNodes across them to s.Strategy.
+func (s *StrategyNodeGroupSetProcessor) BalanceScaleUpBetweenGroups(context *context.AutoscalingContext, groups []cloudprovider.NodeGroup, newNodes int) ([]ScaleUpInfo, errors.AutoscalerError) {
+	scaleUpInfos, err := buildScaleUpInfos(groups)
+	if err != nil {
+		return nil, err
+	}
+	rank := s.Rank
+	if rank == nil {
+		rank = evenWeight
+	}
+	s.Strategy(scaleUpInfos, newNodes, rank)
+	return changedScaleUpInfos(scaleUpInfos), nil
+}
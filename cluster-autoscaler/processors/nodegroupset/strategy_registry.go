@@ -0,0 +1,122 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// BalanceFunc implements a pluggable balancing strategy: given the node groups that still have
+// room to grow (infos) and the number of newNodes to add across them, it must update each info's
+// NewSize (never exceeding MaxSize) to reflect how many of newNodes that group receives. rank
+// supplies a strategy-specific per-group score (a weight for "even"/"weighted", a priority tier
+// for "priority", a waste score for "least-waste"); strategies that don't need one are free to
+// ignore it. Out-of-tree cloud providers can implement and register their own BalanceFunc via
+// RegisterBalancingStrategy instead of forking this package.
+type BalanceFunc func(infos []*ScaleUpInfo, newNodes int, rank func(id string) int)
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = map[string]BalanceFunc{
+		"even":        evenBalanceFunc,
+		"weighted":    weightedBalanceFunc,
+		"priority":    priorityBalanceFunc,
+		"least-waste": leastWasteBalanceFunc,
+	}
+)
+
+// RegisterBalancingStrategy registers fn under name, making it selectable via the
+// BalancingStrategy field of config.NodeGroupDifferenceRatios. Registering a name that's already
+// taken, including one of the built-in strategies, replaces the previous registration.
+func RegisterBalancingStrategy(name string, fn BalanceFunc) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	strategies[name] = fn
+}
+
+// GetBalancingStrategy looks up a previously registered BalanceFunc by name. An empty name
+// resolves to the built-in "even" strategy; any other unregistered name is an error.
+func GetBalancingStrategy(name string) (BalanceFunc, error) {
+	if name == "" {
+		name = "even"
+	}
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	fn, found := strategies[name]
+	if !found {
+		return nil, fmt.Errorf("unknown balancing strategy %q", name)
+	}
+	return fn, nil
+}
+
+func evenBalanceFunc(infos []*ScaleUpInfo, newNodes int, _ func(id string) int) {
+	distributeScaleUpBetweenGroups(infos, newNodes, evenWeight)
+}
+
+func weightedBalanceFunc(infos []*ScaleUpInfo, newNodes int, rank func(id string) int) {
+	distributeScaleUpBetweenGroups(infos, newNodes, rank)
+}
+
+// priorityBalanceFunc fills the group with the highest rank (priority tier) first, up to its Max
+// size, before moving on to the next-highest-ranked group; ties are broken by Id for a
+// deterministic order. A group that's already at its Max size (e.g. the top-priority one is
+// capped) is simply skipped, and its share of newNodes overflows to the next group in order.
+func priorityBalanceFunc(infos []*ScaleUpInfo, newNodes int, rank func(id string) int) {
+	sequentialFill(infos, newNodes, rank, true)
+}
+
+// leastWasteBalanceFunc routes newNodes to the group with the lowest rank (a waste score, where
+// lower means less leftover CPU/mem per node) first, filling it up to its Max size before
+// spilling the overflow onto the next-least-wasteful group.
+func leastWasteBalanceFunc(infos []*ScaleUpInfo, newNodes int, rank func(id string) int) {
+	sequentialFill(infos, newNodes, rank, false)
+}
+
+// sequentialFill sorts infos by rank (descending if highFirst, ascending otherwise, ties broken
+// by Id) and fills them one at a time, up to their Max size, until newNodes is exhausted.
+func sequentialFill(infos []*ScaleUpInfo, newNodes int, rank func(id string) int, highFirst bool) {
+	sorted := append([]*ScaleUpInfo(nil), infos...)
+	sort.Slice(sorted, func(i, j int) bool {
+		ri, rj := rank(sorted[i].Group.Id()), rank(sorted[j].Group.Id())
+		if ri != rj {
+			if highFirst {
+				return ri > rj
+			}
+			return ri < rj
+		}
+		return sorted[i].Group.Id() < sorted[j].Group.Id()
+	})
+
+	remaining := newNodes
+	for _, info := range sorted {
+		if remaining <= 0 {
+			break
+		}
+		room := info.MaxSize - info.NewSize
+		if room <= 0 {
+			continue
+		}
+		add := remaining
+		if add > room {
+			add = room
+		}
+		info.NewSize += add
+		remaining -= add
+	}
+}
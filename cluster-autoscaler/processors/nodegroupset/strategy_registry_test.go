@@ -0,0 +1,129 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+)
+
+func newStrategyProcessor(t *testing.T, strategyName string, rank map[string]int) NodeGroupSetProcessor {
+	strategy, err := GetBalancingStrategy(strategyName)
+	assert.NoError(t, err)
+	return &StrategyNodeGroupSetProcessor{
+		Strategy: strategy,
+		Rank: func(id string) int {
+			return rank[id]
+		},
+	}
+}
+
+func TestGetBalancingStrategyUnknownName(t *testing.T) {
+	_, err := GetBalancingStrategy("does-not-exist")
+	assert.Error(t, err)
+}
+
+func TestGetBalancingStrategyEmptyNameFallsBackToEven(t *testing.T) {
+	strategy, err := GetBalancingStrategy("")
+	assert.NoError(t, err)
+	even, _ := GetBalancingStrategy("even")
+	assert.Equal(t, reflect.ValueOf(even).Pointer(), reflect.ValueOf(strategy).Pointer())
+}
+
+func TestRegisterBalancingStrategy(t *testing.T) {
+	called := false
+	RegisterBalancingStrategy("custom-test-strategy", func(infos []*ScaleUpInfo, newNodes int, rank func(id string) int) {
+		called = true
+	})
+	strategy, err := GetBalancingStrategy("custom-test-strategy")
+	assert.NoError(t, err)
+	strategy(nil, 0, nil)
+	assert.True(t, called)
+}
+
+func TestPriorityStrategyOrdering(t *testing.T) {
+	processor := newStrategyProcessor(t, "priority", map[string]int{"ng1": 1, "ng2": 3, "ng3": 2})
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNodeGroup("ng2", 1, 10, 1)
+	provider.AddNodeGroup("ng3", 1, 10, 1)
+
+	// Only enough nodes for the single highest-priority group (ng2).
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(scaleUpInfo))
+	assert.Equal(t, "ng2", scaleUpInfo[0].Group.Id())
+	assert.Equal(t, 3, scaleUpInfo[0].NewSize)
+}
+
+func TestPriorityStrategyTieBreaking(t *testing.T) {
+	// ng1 and ng2 share the same priority, so the lower Id is filled first.
+	processor := newStrategyProcessor(t, "priority", map[string]int{"ng1": 1, "ng2": 1})
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNodeGroup("ng2", 1, 10, 1)
+
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(scaleUpInfo))
+	assert.Equal(t, "ng1", scaleUpInfo[0].Group.Id())
+	assert.Equal(t, 3, scaleUpInfo[0].NewSize)
+}
+
+func TestPriorityStrategyFallbackWhenTopPriorityCapped(t *testing.T) {
+	// ng2 has the highest priority and still has room to grow (it's not filtered out by
+	// buildScaleUpInfos), but that room is only 1 node -- less than newNodes -- so
+	// sequentialFill's own room <= 0 skip-and-overflow logic has to cap ng2 at its Max and spill
+	// the rest onto ng1, the next group in priority order.
+	processor := newStrategyProcessor(t, "priority", map[string]int{"ng1": 1, "ng2": 3})
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNodeGroup("ng2", 1, 2, 1)
+
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 2)
+	assert.NoError(t, err)
+	scaleUpMap := toScaleUpMap(scaleUpInfo)
+	assert.Equal(t, 2, scaleUpMap["ng2"].NewSize, "ng2 should be filled up to its Max first")
+	assert.Equal(t, 2, scaleUpMap["ng1"].NewSize, "the node that didn't fit in ng2 should overflow to ng1")
+}
+
+func TestLeastWasteStrategyPrefersLowestScore(t *testing.T) {
+	// ng2 wastes less per node (lower rank) than ng1, so it's filled first.
+	processor := newStrategyProcessor(t, "least-waste", map[string]int{"ng1": 500, "ng2": 50})
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNodeGroup("ng2", 1, 10, 1)
+
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(scaleUpInfo))
+	assert.Equal(t, "ng2", scaleUpInfo[0].Group.Id())
+	assert.Equal(t, 3, scaleUpInfo[0].NewSize)
+}
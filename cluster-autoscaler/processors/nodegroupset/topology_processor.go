@@ -0,0 +1,179 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"sort"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+)
+
+// TopologyBalancer balances scale-up between similar node groups like BalancingNodeGroupSetProcessor,
+// but additionally biases the allocation to reduce the skew across a topology key (typically the
+// node's zone), analogous to a pod topology spread constraint applied at the node-group layer
+// instead of the pod layer.
+type TopologyBalancer struct {
+	BalancingNodeGroupSetProcessor
+	// Zone returns the topology partition (e.g. zone) that a node group belongs to.
+	Zone func(groupId string) string
+	// MaxSkew bounds how many nodes are added to the currently least-loaded partition before
+	// re-checking whether another partition has since become less loaded and should take over.
+	// A smaller MaxSkew re-balances more eagerly and so tends to produce a tighter final spread; a
+	// MaxSkew <= 0 disables the topology bias entirely and falls back to a strictly even,
+	// zone-agnostic split (the same as BalancingNodeGroupSetProcessor).
+	MaxSkew int
+}
+
+// NewTopologyBalancer returns a NodeGroupSetProcessor that balances scale-up between similar node
+// groups while biasing the allocation to spread new nodes evenly across zone (or any other
+// topology key returned by zone).
+func NewTopologyBalancer(ignoredLabels []string, ratioOpts config.NodeGroupDifferenceRatios, zone func(groupId string) string, maxSkew int) NodeGroupSetProcessor {
+	return &TopologyBalancer{
+		BalancingNodeGroupSetProcessor: BalancingNodeGroupSetProcessor{
+			Comparator: CreateGenericNodeInfoComparator(ignoredLabels, ratioOpts),
+		},
+		Zone:    zone,
+		MaxSkew: maxSkew,
+	}
+}
+
+// BalanceScaleUpBetweenGroups distributes newNodes across groups, repeatedly handing up to
+// MaxSkew nodes to the least-loaded zone that still has room, so that a zone which has already
+// fallen behind catches up before a zone that's ahead gets any more. A zone whose groups are all
+// at Max size is dropped from consideration and its share overflows onto the remaining zones.
+func (tb *TopologyBalancer) BalanceScaleUpBetweenGroups(context *context.AutoscalingContext, groups []cloudprovider.NodeGroup, newNodes int) ([]ScaleUpInfo, errors.AutoscalerError) {
+	scaleUpInfos, err := buildScaleUpInfos(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	if tb.MaxSkew <= 0 || tb.Zone == nil {
+		distributeScaleUpBetweenGroups(scaleUpInfos, newNodes, evenWeight)
+		return changedScaleUpInfos(scaleUpInfos), nil
+	}
+
+	byZone := make(map[string][]*ScaleUpInfo)
+	for _, info := range scaleUpInfos {
+		zone := tb.Zone(info.Group.Id())
+		byZone[zone] = append(byZone[zone], info)
+	}
+
+	zoneCount, cerr := zoneNodeCounts(groups, tb.Zone)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	remaining := newNodes
+	for remaining > 0 {
+		zone, found := leastLoadedZoneWithRoom(byZone, zoneCount)
+		if !found {
+			break
+		}
+		batch := tb.MaxSkew
+		if batch > remaining {
+			batch = remaining
+		}
+		added := fillZone(byZone[zone], batch)
+		if added == 0 {
+			break
+		}
+		zoneCount[zone] += added
+		remaining -= added
+	}
+	return changedScaleUpInfos(scaleUpInfos), nil
+}
+
+// zoneNodeCounts sums the current size of every group in groups, keyed by the zone returned by
+// zoneFn. Unlike scaleUpInfos (built by buildScaleUpInfos), this includes groups that are already
+// at their Max size, so a zone isn't undercounted just because one of its groups can no longer
+// grow -- it still occupies real capacity that the spread decision needs to know about.
+func zoneNodeCounts(groups []cloudprovider.NodeGroup, zoneFn func(groupId string) string) (map[string]int, errors.AutoscalerError) {
+	counts := make(map[string]int)
+	for _, ng := range groups {
+		currentSize, err := ng.TargetSize()
+		if err != nil {
+			return nil, errors.ToAutoscalerError(errors.CloudProviderError, err)
+		}
+		counts[zoneFn(ng.Id())] += currentSize
+	}
+	return counts, nil
+}
+
+// leastLoadedZoneWithRoom returns the zone with the lowest node count that still has at least one
+// group below its Max size, breaking ties by zone name for determinism.
+func leastLoadedZoneWithRoom(byZone map[string][]*ScaleUpInfo, zoneCount map[string]int) (string, bool) {
+	zones := make([]string, 0, len(byZone))
+	for zone := range byZone {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+
+	best := ""
+	bestCount := 0
+	found := false
+	for _, zone := range zones {
+		if !zoneHasRoom(byZone[zone]) {
+			continue
+		}
+		if !found || zoneCount[zone] < bestCount {
+			best, bestCount, found = zone, zoneCount[zone], true
+		}
+	}
+	return best, found
+}
+
+func zoneHasRoom(infos []*ScaleUpInfo) bool {
+	for _, info := range infos {
+		if info.NewSize < info.MaxSize {
+			return true
+		}
+	}
+	return false
+}
+
+// fillZone adds up to want nodes across infos (all belonging to the same zone), one at a time to
+// whichever group in the zone is currently least loaded (ties broken by Id), and returns how many
+// were actually added -- fewer than want if the zone ran out of room first.
+func fillZone(infos []*ScaleUpInfo, want int) int {
+	added := 0
+	for added < want {
+		target := leastLoadedGroupWithRoom(infos)
+		if target == nil {
+			break
+		}
+		target.NewSize++
+		added++
+	}
+	return added
+}
+
+func leastLoadedGroupWithRoom(infos []*ScaleUpInfo) *ScaleUpInfo {
+	var best *ScaleUpInfo
+	for _, info := range infos {
+		if info.NewSize >= info.MaxSize {
+			continue
+		}
+		if best == nil || info.NewSize < best.NewSize ||
+			(info.NewSize == best.NewSize && info.Group.Id() < best.Group.Id()) {
+			best = info
+		}
+	}
+	return best
+}
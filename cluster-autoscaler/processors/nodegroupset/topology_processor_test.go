@@ -0,0 +1,128 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+)
+
+func threeZoneTopologyBalancer(maxSkew int) *TopologyBalancer {
+	zones := map[string]string{"ng1": "z1", "ng2": "z2", "ng3": "z3"}
+	return &TopologyBalancer{
+		BalancingNodeGroupSetProcessor: BalancingNodeGroupSetProcessor{
+			Comparator: CreateGenericNodeInfoComparator([]string{}, config.NodeGroupDifferenceRatios{}),
+		},
+		Zone:    func(groupId string) string { return zones[groupId] },
+		MaxSkew: maxSkew,
+	}
+}
+
+func TestTopologyBalancerEvenSpreadAcrossZones(t *testing.T) {
+	processor := threeZoneTopologyBalancer(1)
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNodeGroup("ng2", 1, 10, 1)
+	provider.AddNodeGroup("ng3", 1, 10, 1)
+
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 7)
+	assert.NoError(t, err)
+	scaleUpMap := toScaleUpMap(scaleUpInfo)
+	assert.Equal(t, 4, scaleUpMap["ng1"].NewSize)
+	assert.Equal(t, 3, scaleUpMap["ng2"].NewSize)
+	assert.Equal(t, 3, scaleUpMap["ng3"].NewSize)
+}
+
+func TestTopologyBalancerOneZoneMaxedOverflowsToOthers(t *testing.T) {
+	processor := threeZoneTopologyBalancer(1)
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 1, 1) // z1 is already maxed out
+	provider.AddNodeGroup("ng2", 1, 10, 1)
+	provider.AddNodeGroup("ng3", 1, 10, 1)
+
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 4)
+	assert.NoError(t, err)
+	scaleUpMap := toScaleUpMap(scaleUpInfo)
+	_, ng1Scaled := scaleUpMap["ng1"]
+	assert.False(t, ng1Scaled)
+	assert.Equal(t, 3, scaleUpMap["ng2"].NewSize)
+	assert.Equal(t, 3, scaleUpMap["ng3"].NewSize)
+}
+
+func TestTopologyBalancerZoneCountIncludesMaxedOutGroups(t *testing.T) {
+	// z1 = {ngA: maxed at 3/3, ngB: 0/10}, z2 = {ngC: 1/10}. True occupancy is z1=3, z2=1, so z2
+	// is the least-loaded zone and should receive the new node even though ngA -- z1's only
+	// currently-occupied group -- has no room left to grow itself.
+	zones := map[string]string{"ngA": "z1", "ngB": "z1", "ngC": "z2"}
+	processor := &TopologyBalancer{
+		BalancingNodeGroupSetProcessor: BalancingNodeGroupSetProcessor{
+			Comparator: CreateGenericNodeInfoComparator([]string{}, config.NodeGroupDifferenceRatios{}),
+		},
+		Zone:    func(groupId string) string { return zones[groupId] },
+		MaxSkew: 1,
+	}
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ngA", 1, 3, 3)
+	provider.AddNodeGroup("ngB", 0, 10, 0)
+	provider.AddNodeGroup("ngC", 1, 10, 1)
+
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 1)
+	assert.NoError(t, err)
+	scaleUpMap := toScaleUpMap(scaleUpInfo)
+	_, ngBScaled := scaleUpMap["ngB"]
+	assert.False(t, ngBScaled, "z1 is already at true occupancy 3 vs z2's 1, so the new node should go to z2")
+	assert.Equal(t, 2, scaleUpMap["ngC"].NewSize)
+}
+
+func TestTopologyBalancerMaxSkewChangesDistribution(t *testing.T) {
+	tightProcessor := threeZoneTopologyBalancer(1)
+	looseProcessor := threeZoneTopologyBalancer(3)
+	context := &context.AutoscalingContext{}
+
+	newProvider := func() *testprovider.TestCloudProvider {
+		provider := testprovider.NewTestCloudProvider(nil, nil)
+		provider.AddNodeGroup("ng1", 1, 10, 1)
+		provider.AddNodeGroup("ng2", 1, 10, 1)
+		provider.AddNodeGroup("ng3", 1, 10, 1)
+		return provider
+	}
+
+	tightInfo, err := tightProcessor.BalanceScaleUpBetweenGroups(context, newProvider().NodeGroups(), 7)
+	assert.NoError(t, err)
+	looseInfo, err := looseProcessor.BalanceScaleUpBetweenGroups(context, newProvider().NodeGroups(), 7)
+	assert.NoError(t, err)
+
+	tightMap := toScaleUpMap(tightInfo)
+	looseMap := toScaleUpMap(looseInfo)
+
+	// MaxSkew=1 re-balances every node, keeping the spread within 1; MaxSkew=3 lets a zone run
+	// ahead by up to 3 before handing nodes to the next one, producing a lopsided distribution.
+	assert.Equal(t, 1, tightMap["ng1"].NewSize-tightMap["ng2"].NewSize)
+	assert.Equal(t, 2, looseMap["ng1"].NewSize-looseMap["ng3"].NewSize)
+	assert.NotEqual(t, tightMap["ng3"].NewSize, looseMap["ng3"].NewSize)
+}
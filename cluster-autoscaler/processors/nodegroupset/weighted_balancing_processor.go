@@ -0,0 +1,74 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+)
+
+// DefaultNodeGroupWeight is the balancing weight assigned to a node group that doesn't have an
+// explicit weight configured. It makes an unweighted group count the same as any other unweighted
+// group, so a set of groups with no weights configured at all balances evenly, just like
+// BalancingNodeGroupSetProcessor.
+const DefaultNodeGroupWeight = 1
+
+// WeightedBalancingNodeGroupSetProcessor balances scale-up between similar node groups
+// proportionally to a per-group weight, instead of splitting new nodes evenly. A node group with
+// twice the weight of another ends up, modulo Max size caps, with twice as many new nodes. This
+// lets users steer a disproportionate share of scale-ups towards e.g. a cheaper spot pool that's
+// otherwise indistinguishable from an on-demand pool of the same shape.
+type WeightedBalancingNodeGroupSetProcessor struct {
+	BalancingNodeGroupSetProcessor
+	// Weights maps a node group Id() to its balancing weight. Groups missing from the map, or
+	// configured with a weight <= 0, fall back to DefaultNodeGroupWeight.
+	Weights map[string]int
+}
+
+// NewWeightedNodeGroupSetProcessor returns a NodeGroupSetProcessor that balances scale-up between
+// similar node groups proportionally to weights, falling back to an even split for any group
+// missing from weights.
+func NewWeightedNodeGroupSetProcessor(ignoredLabels []string, ratioOpts config.NodeGroupDifferenceRatios, weights map[string]int) NodeGroupSetProcessor {
+	return &WeightedBalancingNodeGroupSetProcessor{
+		BalancingNodeGroupSetProcessor: BalancingNodeGroupSetProcessor{
+			Comparator: CreateGenericNodeInfoComparator(ignoredLabels, ratioOpts),
+		},
+		Weights: weights,
+	}
+}
+
+// BalanceScaleUpBetweenGroups distributes newNodes across groups proportionally to their
+// configured weight, water-filling so that no group ever exceeds its Max size: groups are grown in
+// order of currentSize/weight, the least loaded first, and a group that hits its Max is dropped
+// from the pool and the remaining nodes recomputed across what's left.
+func (w *WeightedBalancingNodeGroupSetProcessor) BalanceScaleUpBetweenGroups(context *context.AutoscalingContext, groups []cloudprovider.NodeGroup, newNodes int) ([]ScaleUpInfo, errors.AutoscalerError) {
+	scaleUpInfos, err := buildScaleUpInfos(groups)
+	if err != nil {
+		return nil, err
+	}
+	distributeScaleUpBetweenGroups(scaleUpInfos, newNodes, w.weightOf)
+	return changedScaleUpInfos(scaleUpInfos), nil
+}
+
+func (w *WeightedBalancingNodeGroupSetProcessor) weightOf(id string) int {
+	if weight, found := w.Weights[id]; found && weight > 0 {
+		return weight
+	}
+	return DefaultNodeGroupWeight
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroupset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+)
+
+func TestWeightedBalanceUnderMaxSize(t *testing.T) {
+	weights := map[string]int{"ng1": 1, "ng2": 3}
+	processor := NewWeightedNodeGroupSetProcessor([]string{}, config.NodeGroupDifferenceRatios{}, weights)
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNodeGroup("ng2", 1, 10, 1)
+
+	// ng2 carries 3x the weight of ng1, so it should receive 3x as many of the 4 new nodes.
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(scaleUpInfo))
+	scaleUpMap := toScaleUpMap(scaleUpInfo)
+	assert.Equal(t, 2, scaleUpMap["ng1"].NewSize)
+	assert.Equal(t, 4, scaleUpMap["ng2"].NewSize)
+}
+
+func TestWeightedBalanceHittingMaxSize(t *testing.T) {
+	weights := map[string]int{"ng1": 1, "ng2": 3}
+	processor := NewWeightedNodeGroupSetProcessor([]string{}, config.NodeGroupDifferenceRatios{}, weights)
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 3, 1)
+	provider.AddNodeGroup("ng2", 1, 20, 1)
+
+	// ng1 caps out at 3 once it's received its proportional share; the overflow it can no longer
+	// absorb is redistributed to ng2.
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(scaleUpInfo))
+	scaleUpMap := toScaleUpMap(scaleUpInfo)
+	assert.Equal(t, 3, scaleUpMap["ng1"].NewSize)
+	assert.Equal(t, 9, scaleUpMap["ng2"].NewSize)
+}
+
+func TestWeightedBalanceDefaultsToEven(t *testing.T) {
+	// Groups missing from the weight map fall back to DefaultNodeGroupWeight, so a processor with
+	// no weights configured at all balances exactly like BalancingNodeGroupSetProcessor.
+	processor := NewWeightedNodeGroupSetProcessor([]string{}, config.NodeGroupDifferenceRatios{}, nil)
+	context := &context.AutoscalingContext{}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNodeGroup("ng2", 1, 10, 3)
+
+	scaleUpInfo, err := processor.BalanceScaleUpBetweenGroups(context, provider.NodeGroups(), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(scaleUpInfo))
+	scaleUpMap := toScaleUpMap(scaleUpInfo)
+	assert.Equal(t, 4, scaleUpMap["ng1"].NewSize)
+	assert.Equal(t, 4, scaleUpMap["ng2"].NewSize)
+}